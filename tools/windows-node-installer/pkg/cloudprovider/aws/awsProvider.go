@@ -0,0 +1,448 @@
+// Package aws implements the cloudprovider.Cloud interface on top of Amazon EC2, creating and
+// destroying the Windows instances and security groups the Windows node installer manages.
+package aws
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/openshift/windows-machine-config-bootstrapper/tools/windows-node-installer/pkg/cloudprovider/aws/client"
+	"github.com/openshift/windows-machine-config-bootstrapper/tools/windows-node-installer/pkg/cloudprovider/aws/client/fake"
+	"github.com/openshift/windows-machine-config-bootstrapper/tools/windows-node-installer/pkg/resource"
+	"github.com/openshift/windows-machine-config-bootstrapper/tools/windows-node-installer/pkg/types"
+)
+
+// useFakeClientEnvVar lets local development run against the in-memory fake EC2 client instead
+// of live AWS, without needing credentials or a real cluster. It does not cover IAM: anything
+// that calls GetIAMWorkerRole still talks to the real IAM API.
+const useFakeClientEnvVar = "WNI_AWS_FAKE_CLIENT"
+
+// fakeInfraIDEnvVar sets the infrastructure ID the fake EC2 client is seeded with, so that
+// WNI_AWS_FAKE_CLIENT can run fully offline instead of needing a real kubeconfig to read one
+// from.
+const fakeInfraIDEnvVar = "WNI_AWS_FAKE_INFRA_ID"
+
+// defaultFakeInfraID is used when fakeInfraIDEnvVar is unset.
+const defaultFakeInfraID = "fake-infra"
+
+// AwsProvider holds the state the AWS cloud provider needs to create and destroy Windows
+// instances in a single OpenShift cluster's VPC.
+type AwsProvider struct {
+	// EC2 is the client used for all EC2 API calls. It is a client.EC2Client rather than a
+	// concrete *ec2.EC2 so that tests can substitute the in-memory fake.
+	EC2   client.EC2Client
+	IAM   *iam.IAM
+	ELBV2 *elbv2.ELBV2
+
+	imageID            string
+	instanceType       string
+	sshKey             string
+	privateKeyPath     string
+	infraID            string
+	resourceTrackerDir string
+
+	// privateOnly places the created instance in a private, NAT-routed subnet with no public IP
+	// and restricts its security group to the VPC CIDR, instead of the default internet-facing
+	// behavior. Set it with SetPrivateOnly before calling CreateWindowsVM.
+	privateOnly bool
+
+	// bootstrapConfig, when set, is rendered into EC2 UserData so the instance bootstraps and
+	// joins the cluster on first boot instead of waiting for a caller to SSH in. Set it with
+	// SetBootstrapConfig before calling CreateWindowsVM.
+	bootstrapConfig *types.BootstrapConfig
+}
+
+// New creates a new AwsProvider, wiring up the real EC2 client unless the fake client env var is
+// set, in which case it uses the in-memory fake instead and never touches the kubeconfig or live
+// AWS credentials.
+func New(kubeconfigPath, credentialsPath, credentialAccountID, resourceTrackerDir, imageID,
+	instanceType, sshKey, privateKeyPath string) (*AwsProvider, error) {
+	if os.Getenv(useFakeClientEnvVar) != "" {
+		infraID := os.Getenv(fakeInfraIDEnvVar)
+		if infraID == "" {
+			infraID = defaultFakeInfraID
+		}
+		return &AwsProvider{
+			EC2:                fake.NewClient(infraID),
+			imageID:            imageID,
+			instanceType:       instanceType,
+			sshKey:             sshKey,
+			privateKeyPath:     privateKeyPath,
+			infraID:            infraID,
+			resourceTrackerDir: resourceTrackerDir,
+		}, nil
+	}
+
+	infraID, err := infraIDFromKubeconfig(kubeconfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("error getting infrastructure ID: %v", err)
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Credentials: credentials.NewSharedCredentials(credentialsPath, credentialAccountID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating AWS session: %v", err)
+	}
+	return &AwsProvider{
+		EC2:                ec2.New(sess),
+		IAM:                iam.New(sess),
+		ELBV2:              elbv2.New(sess),
+		imageID:            imageID,
+		instanceType:       instanceType,
+		sshKey:             sshKey,
+		privateKeyPath:     privateKeyPath,
+		infraID:            infraID,
+		resourceTrackerDir: resourceTrackerDir,
+	}, nil
+}
+
+// GetInfraID returns the infrastructure ID of the OpenShift cluster this provider was created
+// against.
+func (a *AwsProvider) GetInfraID() (string, error) {
+	if a.infraID == "" {
+		return "", fmt.Errorf("infrastructure ID is not set")
+	}
+	return a.infraID, nil
+}
+
+// SetPrivateOnly configures whether the next CreateWindowsVM call creates an internal-only
+// instance: no public IP, placed in a private NAT-routed subnet, with its security group
+// restricted to the VPC CIDR and registered against the cluster's internal API load balancer
+// instead of the public one.
+func (a *AwsProvider) SetPrivateOnly(privateOnly bool) {
+	a.privateOnly = privateOnly
+}
+
+// SetBootstrapConfig configures the next CreateWindowsVM call to install the kubelet, write the
+// kubeconfig, and join the cluster via EC2 UserData on first boot, instead of requiring the
+// caller to SSH in and bootstrap the instance themselves.
+func (a *AwsProvider) SetBootstrapConfig(config *types.BootstrapConfig) {
+	a.bootstrapConfig = config
+}
+
+// CreateWindowsVM creates a Windows instance with a dedicated security group attached to the
+// cluster's VPC and worker security group, and records the created resources in the installer
+// info file so that a later teardown can find them.
+func (a *AwsProvider) CreateWindowsVM() (*types.Credentials, error) {
+	vpc, err := a.GetVPCByInfrastructure(a.infraID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting cluster VPC: %v", err)
+	}
+
+	sgID, err := a.createWindowsWorkerSG(*vpc.VpcId)
+	if err != nil {
+		return nil, fmt.Errorf("error creating Windows worker security group: %v", err)
+	}
+
+	imageID := a.imageID
+	if imageID == "" {
+		imageID, err = a.getLatestWindowsAMI()
+		if err != nil {
+			return nil, fmt.Errorf("error finding latest Windows AMI: %v", err)
+		}
+	}
+
+	workerSG, err := a.GetClusterWorkerSGID(a.infraID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting cluster worker security group: %v", err)
+	}
+
+	runInput := &ec2.RunInstancesInput{
+		ImageId:          aws.String(imageID),
+		InstanceType:     aws.String(a.instanceType),
+		MinCount:         aws.Int64(1),
+		MaxCount:         aws.Int64(1),
+		SecurityGroupIds: aws.StringSlice([]string{sgID, workerSG}),
+	}
+	// An SSH key is only needed when the caller intends to log in after boot; bootstrap-config
+	// instances install themselves via UserData and never need one.
+	if a.sshKey != "" {
+		runInput.KeyName = aws.String(a.sshKey)
+	}
+	if a.privateOnly {
+		subnetID, err := a.GetPrivateSubnetByInfrastructure(a.infraID)
+		if err != nil {
+			return nil, fmt.Errorf("error finding private subnet: %v", err)
+		}
+		runInput.SubnetId = aws.String(subnetID)
+	}
+	if a.bootstrapConfig != nil {
+		userData, err := a.bootstrapConfig.UserDataScript()
+		if err != nil {
+			return nil, fmt.Errorf("error rendering bootstrap user data: %v", err)
+		}
+		runInput.UserData = aws.String(base64.StdEncoding.EncodeToString([]byte(userData)))
+	}
+
+	reservation, err := a.EC2.RunInstances(runInput)
+	if err != nil {
+		return nil, fmt.Errorf("error running Windows instance: %v", err)
+	}
+	instance := reservation.Instances[0]
+
+	if _, err := a.EC2.CreateTags(&ec2.CreateTagsInput{
+		Resources: []*string{instance.InstanceId},
+		Tags: []*ec2.Tag{
+			{Key: aws.String("Name"), Value: aws.String("windows-worker-" + a.infraID)},
+			{Key: aws.String("kubernetes.io/cluster/" + a.infraID), Value: aws.String("owned")},
+		},
+	}); err != nil {
+		return nil, fmt.Errorf("error tagging Windows instance: %v", err)
+	}
+
+	if a.privateOnly {
+		if err := a.registerWithInternalLoadBalancer(*instance.InstanceId); err != nil {
+			return nil, fmt.Errorf("error registering instance with internal load balancer: %v", err)
+		}
+	}
+
+	if err := a.recordCreatedResources(*instance.InstanceId, sgID); err != nil {
+		return nil, fmt.Errorf("error recording created resources: %v", err)
+	}
+
+	return types.NewCredentials(*instance.InstanceId, aws.StringValue(instance.PublicIpAddress), ""), nil
+}
+
+// DestroyWindowsVMs destroys every instance and security group recorded in the installer info
+// file.
+func (a *AwsProvider) DestroyWindowsVMs() error {
+	infoPath := a.installerInfoPath()
+	info, err := resource.ReadInstallerInfo(infoPath)
+	if err != nil {
+		return fmt.Errorf("error reading installer info: %v", err)
+	}
+
+	for _, instanceID := range info.InstanceIDs {
+		if err := a.TerminateInstance(instanceID); err != nil {
+			return fmt.Errorf("error terminating instance %s: %v", instanceID, err)
+		}
+	}
+	for _, sgID := range info.SecurityGroupIDs {
+		if err := a.DeleteSG(sgID); err != nil {
+			return fmt.Errorf("error deleting security group %s: %v", sgID, err)
+		}
+	}
+
+	return resource.RemoveInstallerInfo(infoPath)
+}
+
+// TerminateInstance terminates the instance with the given id.
+func (a *AwsProvider) TerminateInstance(instanceID string) error {
+	_, err := a.EC2.TerminateInstances(&ec2.TerminateInstancesInput{
+		InstanceIds: aws.StringSlice([]string{instanceID}),
+	})
+	return err
+}
+
+// DeleteSG deletes the security group with the given id.
+func (a *AwsProvider) DeleteSG(sgID string) error {
+	_, err := a.EC2.DeleteSecurityGroup(&ec2.DeleteSecurityGroupInput{GroupId: aws.String(sgID)})
+	return err
+}
+
+// GetVPCByInfrastructure returns the VPC tagged with the cluster's infrastructure ID.
+func (a *AwsProvider) GetVPCByInfrastructure(infraID string) (*ec2.Vpc, error) {
+	result, err := a.EC2.DescribeVpcs(&ec2.DescribeVpcsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("tag:kubernetes.io/cluster/" + infraID),
+				Values: aws.StringSlice([]string{"owned", "shared"}),
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Vpcs) < 1 {
+		return nil, fmt.Errorf("no VPC found for infrastructure %s", infraID)
+	}
+	return result.Vpcs[0], nil
+}
+
+// GetClusterWorkerSGID returns the id of the cluster's worker security group.
+func (a *AwsProvider) GetClusterWorkerSGID(infraID string) (string, error) {
+	result, err := a.EC2.DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("group-name"),
+				Values: aws.StringSlice([]string{infraID + "-worker-sg"}),
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(result.SecurityGroups) < 1 {
+		return "", fmt.Errorf("no worker security group found for infrastructure %s", infraID)
+	}
+	return *result.SecurityGroups[0].GroupId, nil
+}
+
+// GetIAMWorkerRole returns the IAM instance profile used by the cluster's worker nodes.
+func (a *AwsProvider) GetIAMWorkerRole(infraID string) (*iam.InstanceProfile, error) {
+	result, err := a.IAM.GetInstanceProfile(&iam.GetInstanceProfileInput{
+		InstanceProfileName: aws.String(infraID + "-worker-profile"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.InstanceProfile, nil
+}
+
+// createWindowsWorkerSG creates a security group in the given VPC that allows RDP and SSH
+// traffic. In the default, internet-facing mode that traffic is scoped to the caller's own IP;
+// in PrivateOnly mode there is no caller IP to reach the instance from, so it is scoped to the
+// VPC CIDR instead, with no myIp/32 rule.
+func (a *AwsProvider) createWindowsWorkerSG(vpcID string) (string, error) {
+	created, err := a.EC2.CreateSecurityGroup(&ec2.CreateSecurityGroupInput{
+		GroupName:   aws.String(a.infraID + "-windows-worker-sg"),
+		Description: aws.String("Windows worker security group for " + a.infraID),
+		VpcId:       aws.String(vpcID),
+	})
+	if err != nil {
+		return "", err
+	}
+	sgID := *created.GroupId
+
+	var ingressCIDR string
+	if a.privateOnly {
+		vpc, err := a.GetVPCByInfrastructure(a.infraID)
+		if err != nil {
+			return "", fmt.Errorf("error getting cluster VPC CIDR: %v", err)
+		}
+		ingressCIDR = *vpc.CidrBlock
+	} else {
+		myIP, err := GetMyIp()
+		if err != nil {
+			return "", fmt.Errorf("error getting caller's public IP: %v", err)
+		}
+		ingressCIDR = myIP + "/32"
+	}
+
+	_, err = a.EC2.AuthorizeSecurityGroupIngress(&ec2.AuthorizeSecurityGroupIngressInput{
+		GroupId: aws.String(sgID),
+		IpPermissions: []*ec2.IpPermission{
+			{
+				IpProtocol: aws.String("tcp"),
+				FromPort:   aws.Int64(3389),
+				ToPort:     aws.Int64(3389),
+				IpRanges:   []*ec2.IpRange{{CidrIp: aws.String(ingressCIDR)}},
+			},
+			{
+				IpProtocol: aws.String("tcp"),
+				FromPort:   aws.Int64(22),
+				ToPort:     aws.Int64(22),
+				IpRanges:   []*ec2.IpRange{{CidrIp: aws.String(ingressCIDR)}},
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return sgID, nil
+}
+
+// GetPrivateSubnetByInfrastructure returns a private, NAT-routed subnet belonging to the
+// cluster's VPC, selected by the "<infraID>-private-*" naming convention OpenShift installs use.
+func (a *AwsProvider) GetPrivateSubnetByInfrastructure(infraID string) (string, error) {
+	result, err := a.EC2.DescribeSubnets(&ec2.DescribeSubnetsInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("tag:Name"),
+				Values: aws.StringSlice([]string{"*" + infraID + "-private-*"}),
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(result.Subnets) < 1 {
+		return "", fmt.Errorf("no private subnet found for infrastructure %s", infraID)
+	}
+	return *result.Subnets[0].SubnetId, nil
+}
+
+// registerWithInternalLoadBalancer registers the instance with the target group behind the
+// cluster's internal API load balancer, so that an internal-only Windows worker is still
+// reachable from the rest of the cluster the way a public one would be via the external LB.
+func (a *AwsProvider) registerWithInternalLoadBalancer(instanceID string) error {
+	groups, err := a.ELBV2.DescribeTargetGroups(&elbv2.DescribeTargetGroupsInput{
+		Names: aws.StringSlice([]string{a.infraID + "-internal"}),
+	})
+	if err != nil {
+		return err
+	}
+	if len(groups.TargetGroups) < 1 {
+		return fmt.Errorf("no internal target group found for infrastructure %s", a.infraID)
+	}
+
+	_, err = a.ELBV2.RegisterTargets(&elbv2.RegisterTargetsInput{
+		TargetGroupArn: groups.TargetGroups[0].TargetGroupArn,
+		Targets:        []*elbv2.TargetDescription{{Id: aws.String(instanceID)}},
+	})
+	return err
+}
+
+// getLatestWindowsAMI returns the id of the most recent Windows Server 2019 with Containers AMI.
+func (a *AwsProvider) getLatestWindowsAMI() (string, error) {
+	result, err := a.EC2.DescribeImages(&ec2.DescribeImagesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("name"),
+				Values: aws.StringSlice([]string{"Windows_Server-2019-English-Full-ContainersLatest*"}),
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(result.Images) < 1 {
+		return "", fmt.Errorf("no Windows Server 2019 Containers AMI found")
+	}
+	return *result.Images[0].ImageId, nil
+}
+
+// recordCreatedResources appends the created instance and security group ids to the installer
+// info file, creating the file if it does not already exist.
+func (a *AwsProvider) recordCreatedResources(instanceID, sgID string) error {
+	info := &resource.InstallerInfo{
+		InstanceIDs:      []string{instanceID},
+		SecurityGroupIDs: []string{sgID},
+		PrivateOnly:      a.privateOnly,
+	}
+	return info.Save(a.installerInfoPath())
+}
+
+func (a *AwsProvider) installerInfoPath() string {
+	return a.resourceTrackerDir + "/windows-node-installer.json"
+}
+
+// GetMyIp returns the public IP address of the machine running the installer, which is used to
+// scope the Windows security group's RDP/SSH ingress rules to the caller only.
+func GetMyIp() (string, error) {
+	resp, err := http.Get("https://checkip.amazonaws.com")
+	if err != nil {
+		return "", fmt.Errorf("error reaching IP lookup service: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading IP lookup response: %v", err)
+	}
+	ip := string(body)
+	if len(ip) > 0 && ip[len(ip)-1] == '\n' {
+		ip = ip[:len(ip)-1]
+	}
+	return ip, nil
+}
@@ -0,0 +1,31 @@
+package aws
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+)
+
+// Error codes AWS can return for a short window after a create or delete call, before the API
+// has caught up with itself. They are not failures so much as "not yet" / "already gone".
+const (
+	errCodeInvalidGroupNotFound    = "InvalidGroup.NotFound"
+	errCodeInvalidInstanceNotFound = "InvalidInstanceID.NotFound"
+)
+
+// IsNotFoundError reports whether err is one of the eventually-consistent AWS not-found errors
+// (InvalidGroup.NotFound, InvalidInstanceID.NotFound). Callers should treat it as retryable when
+// reading state that was just created, and as success when confirming a delete. It understands
+// both the real SDK's awserr.Error and the in-memory fake's plain "<code>: <message>" errors.
+func IsNotFoundError(err error) bool {
+	if err == nil {
+		return false
+	}
+	code := ""
+	if aerr, ok := err.(awserr.Error); ok {
+		code = aerr.Code()
+	} else {
+		code = strings.SplitN(err.Error(), ":", 2)[0]
+	}
+	return code == errCodeInvalidGroupNotFound || code == errCodeInvalidInstanceNotFound
+}
@@ -0,0 +1,514 @@
+// Package fake provides an in-memory implementation of client.EC2Client so that the AWS
+// provider's EC2-facing behavior can be exercised in tests without live AWS credentials or a
+// real cluster. It does not model IAM: AwsProvider.GetIAMWorkerRole still talks to the real IAM
+// API and requires live credentials even when the fake EC2 client is in use.
+package fake
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/openshift/windows-machine-config-bootstrapper/tools/windows-node-installer/pkg/cloudprovider/aws/client"
+)
+
+// Client is an in-memory stand-in for *ec2.EC2, keyed by instance/SG/VPC id. It simulates the
+// state transitions, tag propagation, and associations the AWS provider and its e2e tests rely
+// on.
+type Client struct {
+	mu sync.Mutex
+
+	nextID int
+
+	instances        map[string]*ec2.Instance
+	instanceUserData map[string]string
+	securityGroups   map[string]*ec2.SecurityGroup
+	vpcs             map[string]*ec2.Vpc
+	subnets          map[string]*ec2.Subnet
+	routeTables      map[string]*ec2.RouteTable
+	internetGateways map[string]*ec2.InternetGateway
+	natGateways      map[string]*ec2.NatGateway
+	images           map[string]*ec2.Image
+
+	// defaultSubnetID and defaultPrivateSubnetID are the fixture resources seeded by NewClient,
+	// used when RunInstances is not given an explicit subnet, so callers get a consistent,
+	// pre-wired public/private topology.
+	defaultSubnetID        string
+	defaultPrivateSubnetID string
+	defaultVpcID           string
+	defaultImageID         string
+}
+
+// NewClient returns an empty fake EC2 client seeded with a single default VPC tagged as owned by
+// infraID, a subnet-facing route table with an internet gateway attached, a worker security group
+// named "<infraID>-worker-sg", and a Windows Server 2019 image, which mirrors the minimum fixture
+// every e2e test expects to find for that cluster.
+func NewClient(infraID string) *Client {
+	c := &Client{
+		instances:        map[string]*ec2.Instance{},
+		instanceUserData: map[string]string{},
+		securityGroups:   map[string]*ec2.SecurityGroup{},
+		vpcs:             map[string]*ec2.Vpc{},
+		subnets:          map[string]*ec2.Subnet{},
+		routeTables:      map[string]*ec2.RouteTable{},
+		internetGateways: map[string]*ec2.InternetGateway{},
+		natGateways:      map[string]*ec2.NatGateway{},
+		images:           map[string]*ec2.Image{},
+	}
+
+	igwID := c.genID("igw")
+	c.internetGateways[igwID] = &ec2.InternetGateway{InternetGatewayId: aws.String(igwID)}
+
+	vpcID := c.genID("vpc")
+	c.vpcs[vpcID] = &ec2.Vpc{
+		VpcId:     aws.String(vpcID),
+		CidrBlock: aws.String("10.0.0.0/16"),
+		Tags:      []*ec2.Tag{{Key: aws.String("kubernetes.io/cluster/" + infraID), Value: aws.String("owned")}},
+	}
+
+	workerSgID := c.genID("sg")
+	c.securityGroups[workerSgID] = &ec2.SecurityGroup{
+		GroupId:   aws.String(workerSgID),
+		GroupName: aws.String(infraID + "-worker-sg"),
+		VpcId:     aws.String(vpcID),
+	}
+
+	subnetID := c.genID("subnet")
+	c.subnets[subnetID] = &ec2.Subnet{
+		SubnetId:            aws.String(subnetID),
+		VpcId:               aws.String(vpcID),
+		MapPublicIpOnLaunch: aws.Bool(true),
+		Tags:                []*ec2.Tag{{Key: aws.String("Name"), Value: aws.String("fake-public-subnet")}},
+	}
+	c.routeTables[c.genID("rtb")] = &ec2.RouteTable{
+		Associations: []*ec2.RouteTableAssociation{{SubnetId: aws.String(subnetID)}},
+		Routes:       []*ec2.Route{{GatewayId: aws.String(igwID)}},
+	}
+
+	natGatewayID := c.genID("nat")
+	c.natGateways[natGatewayID] = &ec2.NatGateway{NatGatewayId: aws.String(natGatewayID)}
+
+	privateSubnetID := c.genID("subnet")
+	c.subnets[privateSubnetID] = &ec2.Subnet{
+		SubnetId:            aws.String(privateSubnetID),
+		VpcId:               aws.String(vpcID),
+		MapPublicIpOnLaunch: aws.Bool(false),
+		Tags:                []*ec2.Tag{{Key: aws.String("Name"), Value: aws.String("fake-private-subnet")}},
+	}
+	c.routeTables[c.genID("rtb")] = &ec2.RouteTable{
+		Associations: []*ec2.RouteTableAssociation{{SubnetId: aws.String(privateSubnetID)}},
+		Routes:       []*ec2.Route{{NatGatewayId: aws.String(natGatewayID)}},
+	}
+
+	imageID := c.genID("ami")
+	c.images[imageID] = &ec2.Image{
+		ImageId: aws.String(imageID),
+		Name:    aws.String("Windows_Server-2019-English-Full-ContainersLatest-fake"),
+	}
+
+	c.defaultSubnetID = subnetID
+	c.defaultPrivateSubnetID = privateSubnetID
+	c.defaultVpcID = vpcID
+	c.defaultImageID = imageID
+	return c
+}
+
+var _ client.EC2Client = (*Client)(nil)
+
+func (c *Client) genID(prefix string) string {
+	c.nextID++
+	return fmt.Sprintf("%s-fake%d", prefix, c.nextID)
+}
+
+// RunInstances creates pending instances in the fake and immediately advances them to running,
+// since the e2e suite does not exercise the pending state.
+func (c *Client) RunInstances(in *ec2.RunInstancesInput) (*ec2.Reservation, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	imageID := in.ImageId
+	if imageID == nil {
+		imageID = aws.String(c.defaultImageID)
+	}
+
+	subnetID := c.defaultSubnetID
+	if in.SubnetId != nil {
+		subnetID = *in.SubnetId
+	}
+	subnet := c.subnets[subnetID]
+
+	id := c.genID("i")
+	instance := &ec2.Instance{
+		InstanceId:   aws.String(id),
+		ImageId:      imageID,
+		InstanceType: in.InstanceType,
+		KeyName:      in.KeyName,
+		SubnetId:     aws.String(subnetID),
+		State:        &ec2.InstanceState{Name: aws.String(ec2.InstanceStateNameRunning)},
+	}
+	if subnet == nil || aws.BoolValue(subnet.MapPublicIpOnLaunch) {
+		instance.PublicIpAddress = aws.String("203.0.113.10")
+	}
+	for _, sgID := range in.SecurityGroupIds {
+		if sg, ok := c.securityGroups[*sgID]; ok {
+			instance.SecurityGroups = append(instance.SecurityGroups,
+				&ec2.GroupIdentifier{GroupId: sg.GroupId, GroupName: sg.GroupName})
+		}
+	}
+	c.instances[id] = instance
+	if in.UserData != nil {
+		c.instanceUserData[id] = *in.UserData
+	}
+
+	return &ec2.Reservation{Instances: []*ec2.Instance{instance}}, nil
+}
+
+// TerminateInstances moves the given instances to the terminated state rather than removing
+// them, mirroring real EC2 which keeps terminated instances describable for a time.
+func (c *Client) TerminateInstances(in *ec2.TerminateInstancesInput) (*ec2.TerminateInstancesOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, id := range in.InstanceIds {
+		instance, ok := c.instances[*id]
+		if !ok {
+			return nil, awsErr("InvalidInstanceID.NotFound", fmt.Sprintf("instance %s not found", *id))
+		}
+		instance.State = &ec2.InstanceState{Name: aws.String(ec2.InstanceStateNameTerminated)}
+	}
+	return &ec2.TerminateInstancesOutput{}, nil
+}
+
+// DescribeInstances returns the instances matching the requested ids, or all instances when no
+// ids are given.
+func (c *Client) DescribeInstances(in *ec2.DescribeInstancesInput) (*ec2.DescribeInstancesOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var matched []*ec2.Instance
+	if len(in.InstanceIds) == 0 {
+		for _, instance := range c.instances {
+			matched = append(matched, instance)
+		}
+		if len(matched) == 0 {
+			return &ec2.DescribeInstancesOutput{}, nil
+		}
+		return &ec2.DescribeInstancesOutput{Reservations: []*ec2.Reservation{{Instances: matched}}}, nil
+	}
+
+	for _, id := range in.InstanceIds {
+		instance, ok := c.instances[*id]
+		if !ok {
+			return nil, awsErr("InvalidInstanceID.NotFound", fmt.Sprintf("instance %s not found", *id))
+		}
+		matched = append(matched, instance)
+	}
+	return &ec2.DescribeInstancesOutput{Reservations: []*ec2.Reservation{{Instances: matched}}}, nil
+}
+
+// DescribeInstanceAttribute supports the "userData" attribute, returning the base64 UserData the
+// instance was launched with.
+func (c *Client) DescribeInstanceAttribute(in *ec2.DescribeInstanceAttributeInput) (*ec2.DescribeInstanceAttributeOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if in.Attribute == nil || *in.Attribute != ec2.InstanceAttributeNameUserData {
+		return nil, fmt.Errorf("fake only supports the %s attribute", ec2.InstanceAttributeNameUserData)
+	}
+	if _, ok := c.instances[*in.InstanceId]; !ok {
+		return nil, awsErr("InvalidInstanceID.NotFound", fmt.Sprintf("instance %s not found", *in.InstanceId))
+	}
+
+	output := &ec2.DescribeInstanceAttributeOutput{InstanceId: in.InstanceId}
+	if userData, ok := c.instanceUserData[*in.InstanceId]; ok {
+		output.UserData = &ec2.AttributeValue{Value: aws.String(userData)}
+	}
+	return output, nil
+}
+
+// DescribeInstanceStatus reports "ok" for every running instance, since the fake does not model
+// instance checks failing.
+func (c *Client) DescribeInstanceStatus(in *ec2.DescribeInstanceStatusInput) (*ec2.DescribeInstanceStatusOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var statuses []*ec2.InstanceStatus
+	for _, id := range in.InstanceIds {
+		if _, ok := c.instances[*id]; !ok {
+			return nil, awsErr("InvalidInstanceID.NotFound", fmt.Sprintf("instance %s not found", *id))
+		}
+		statuses = append(statuses, &ec2.InstanceStatus{
+			InstanceId:     id,
+			InstanceStatus: &ec2.InstanceStatusSummary{Status: aws.String("ok")},
+		})
+	}
+	return &ec2.DescribeInstanceStatusOutput{InstanceStatuses: statuses}, nil
+}
+
+// DescribeImages returns the fake images matching the requested ids.
+func (c *Client) DescribeImages(in *ec2.DescribeImagesInput) (*ec2.DescribeImagesOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(in.ImageIds) > 0 {
+		var matched []*ec2.Image
+		for _, id := range in.ImageIds {
+			if image, ok := c.images[*id]; ok {
+				matched = append(matched, image)
+			}
+		}
+		return &ec2.DescribeImagesOutput{Images: matched}, nil
+	}
+
+	nameFilter := ""
+	for _, filter := range in.Filters {
+		if filter.Name != nil && *filter.Name == "name" && len(filter.Values) > 0 {
+			nameFilter = *filter.Values[0]
+		}
+	}
+
+	var matched []*ec2.Image
+	for _, image := range c.images {
+		if nameFilter == "" || imageNameMatches(image, nameFilter) {
+			matched = append(matched, image)
+		}
+	}
+	return &ec2.DescribeImagesOutput{Images: matched}, nil
+}
+
+func imageNameMatches(image *ec2.Image, pattern string) bool {
+	wanted := strings.Trim(pattern, "*")
+	return image.Name != nil && strings.Contains(*image.Name, wanted)
+}
+
+// DescribeSecurityGroups returns security groups by id, or filtered by group-name when a
+// group-name filter is given, or all of them when neither is given. Other filters (the
+// ip-permission.* ones used to look up the Windows worker SG) are not evaluated.
+func (c *Client) DescribeSecurityGroups(in *ec2.DescribeSecurityGroupsInput) (*ec2.DescribeSecurityGroupsOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(in.GroupIds) > 0 {
+		var matched []*ec2.SecurityGroup
+		for _, id := range in.GroupIds {
+			sg, ok := c.securityGroups[*id]
+			if !ok {
+				return nil, awsErr("InvalidGroup.NotFound", fmt.Sprintf("security group %s not found", *id))
+			}
+			matched = append(matched, sg)
+		}
+		return &ec2.DescribeSecurityGroupsOutput{SecurityGroups: matched}, nil
+	}
+
+	nameFilter := ""
+	for _, f := range in.Filters {
+		if f.Name != nil && *f.Name == "group-name" && len(f.Values) > 0 {
+			nameFilter = *f.Values[0]
+		}
+	}
+
+	var matched []*ec2.SecurityGroup
+	for _, sg := range c.securityGroups {
+		if nameFilter == "" || (sg.GroupName != nil && *sg.GroupName == nameFilter) {
+			matched = append(matched, sg)
+		}
+	}
+	return &ec2.DescribeSecurityGroupsOutput{SecurityGroups: matched}, nil
+}
+
+// CreateSecurityGroup creates an empty security group scoped to the requested VPC.
+func (c *Client) CreateSecurityGroup(in *ec2.CreateSecurityGroupInput) (*ec2.CreateSecurityGroupOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id := c.genID("sg")
+	c.securityGroups[id] = &ec2.SecurityGroup{
+		GroupId:   aws.String(id),
+		GroupName: in.GroupName,
+		VpcId:     in.VpcId,
+	}
+	return &ec2.CreateSecurityGroupOutput{GroupId: aws.String(id)}, nil
+}
+
+// AuthorizeSecurityGroupIngress appends the requested ingress rules to the security group's
+// permission list.
+func (c *Client) AuthorizeSecurityGroupIngress(in *ec2.AuthorizeSecurityGroupIngressInput) (*ec2.AuthorizeSecurityGroupIngressOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sg, ok := c.securityGroups[*in.GroupId]
+	if !ok {
+		return nil, awsErr("InvalidGroup.NotFound", fmt.Sprintf("security group %s not found", *in.GroupId))
+	}
+	sg.IpPermissions = append(sg.IpPermissions, in.IpPermissions...)
+	return &ec2.AuthorizeSecurityGroupIngressOutput{}, nil
+}
+
+// DeleteSecurityGroup removes the group from the fake. Deleting an already-deleted group is
+// treated as success, matching AWS's eventually-consistent delete semantics.
+func (c *Client) DeleteSecurityGroup(in *ec2.DeleteSecurityGroupInput) (*ec2.DeleteSecurityGroupOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if in.GroupId != nil {
+		delete(c.securityGroups, *in.GroupId)
+	}
+	return &ec2.DeleteSecurityGroupOutput{}, nil
+}
+
+// DescribeRouteTables returns the fake's route tables, optionally filtered by
+// association.subnet-id, which is the only filter the AWS provider applies.
+func (c *Client) DescribeRouteTables(in *ec2.DescribeRouteTablesInput) (*ec2.DescribeRouteTablesOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	subnetID := ""
+	for _, f := range in.Filters {
+		if f.Name != nil && *f.Name == "association.subnet-id" && len(f.Values) > 0 {
+			subnetID = *f.Values[0]
+		}
+	}
+
+	var matched []*ec2.RouteTable
+	for _, rt := range c.routeTables {
+		if subnetID == "" {
+			matched = append(matched, rt)
+			continue
+		}
+		for _, assoc := range rt.Associations {
+			if assoc.SubnetId != nil && *assoc.SubnetId == subnetID {
+				matched = append(matched, rt)
+				break
+			}
+		}
+	}
+	return &ec2.DescribeRouteTablesOutput{RouteTables: matched}, nil
+}
+
+// DescribeInternetGateways returns the fake's internet gateways matching the requested ids.
+func (c *Client) DescribeInternetGateways(in *ec2.DescribeInternetGatewaysInput) (*ec2.DescribeInternetGatewaysOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var matched []*ec2.InternetGateway
+	for _, id := range in.InternetGatewayIds {
+		if id == nil {
+			continue
+		}
+		if igw, ok := c.internetGateways[*id]; ok {
+			matched = append(matched, igw)
+		}
+	}
+	return &ec2.DescribeInternetGatewaysOutput{InternetGateways: matched}, nil
+}
+
+// DescribeVpcs returns the fake's VPCs matching the requested ids, or all of them when none are
+// given.
+func (c *Client) DescribeVpcs(in *ec2.DescribeVpcsInput) (*ec2.DescribeVpcsOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var matched []*ec2.Vpc
+	if len(in.VpcIds) == 0 {
+		for _, vpc := range c.vpcs {
+			matched = append(matched, vpc)
+		}
+	} else {
+		for _, id := range in.VpcIds {
+			if vpc, ok := c.vpcs[*id]; ok {
+				matched = append(matched, vpc)
+			}
+		}
+	}
+	return &ec2.DescribeVpcsOutput{Vpcs: matched}, nil
+}
+
+// DescribeSubnets returns the fake's subnets matching the requested ids, or filtered by
+// tag:Name when a tag filter is given (supporting the leading/trailing "*" wildcards the AWS
+// provider uses to find a cluster's public/private subnets by naming convention).
+func (c *Client) DescribeSubnets(in *ec2.DescribeSubnetsInput) (*ec2.DescribeSubnetsOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(in.SubnetIds) > 0 {
+		var matched []*ec2.Subnet
+		for _, id := range in.SubnetIds {
+			if subnet, ok := c.subnets[*id]; ok {
+				matched = append(matched, subnet)
+			}
+		}
+		return &ec2.DescribeSubnetsOutput{Subnets: matched}, nil
+	}
+
+	var nameFilter string
+	for _, f := range in.Filters {
+		if f.Name != nil && *f.Name == "tag:Name" && len(f.Values) > 0 {
+			nameFilter = *f.Values[0]
+		}
+	}
+
+	var matched []*ec2.Subnet
+	for _, subnet := range c.subnets {
+		if nameFilter == "" || subnetNameMatches(subnet, nameFilter) {
+			matched = append(matched, subnet)
+		}
+	}
+	return &ec2.DescribeSubnetsOutput{Subnets: matched}, nil
+}
+
+func subnetNameMatches(subnet *ec2.Subnet, pattern string) bool {
+	wanted := strings.Trim(pattern, "*")
+	for _, tag := range subnet.Tags {
+		if tag.Key != nil && *tag.Key == "Name" && tag.Value != nil && strings.Contains(*tag.Value, wanted) {
+			return true
+		}
+	}
+	return false
+}
+
+// DescribeNatGateways returns the fake's NAT gateways matching the requested ids, or all of them
+// when none are given.
+func (c *Client) DescribeNatGateways(in *ec2.DescribeNatGatewaysInput) (*ec2.DescribeNatGatewaysOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var matched []*ec2.NatGateway
+	if len(in.NatGatewayIds) == 0 {
+		for _, nat := range c.natGateways {
+			matched = append(matched, nat)
+		}
+	} else {
+		for _, id := range in.NatGatewayIds {
+			if nat, ok := c.natGateways[*id]; ok {
+				matched = append(matched, nat)
+			}
+		}
+	}
+	return &ec2.DescribeNatGatewaysOutput{NatGateways: matched}, nil
+}
+
+// CreateTags attaches the requested tags to every named resource, merging the fake's tag
+// propagation behavior for instances and security groups.
+func (c *Client) CreateTags(in *ec2.CreateTagsInput) (*ec2.CreateTagsOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, id := range in.Resources {
+		if id == nil {
+			continue
+		}
+		if instance, ok := c.instances[*id]; ok {
+			instance.Tags = append(instance.Tags, in.Tags...)
+		}
+		if sg, ok := c.securityGroups[*id]; ok {
+			sg.Tags = append(sg.Tags, in.Tags...)
+		}
+	}
+	return &ec2.CreateTagsOutput{}, nil
+}
+
+func awsErr(code, message string) error {
+	return fmt.Errorf("%s: %s", code, message)
+}
@@ -0,0 +1,159 @@
+package fake
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/require"
+)
+
+// TestClientInstanceLifecycle verifies that an instance created by RunInstances is immediately
+// describable as running, and moves to terminated once TerminateInstances is called on it.
+func TestClientInstanceLifecycle(t *testing.T) {
+	client := NewClient("test-infra")
+
+	reservation, err := client.RunInstances(&ec2.RunInstancesInput{
+		InstanceType: aws.String("m4.large"),
+		KeyName:      aws.String("libra"),
+		MinCount:     aws.Int64(1),
+		MaxCount:     aws.Int64(1),
+	})
+	require.NoError(t, err)
+	require.Len(t, reservation.Instances, 1)
+	instanceID := *reservation.Instances[0].InstanceId
+
+	described, err := client.DescribeInstances(&ec2.DescribeInstancesInput{
+		InstanceIds: aws.StringSlice([]string{instanceID}),
+	})
+	require.NoError(t, err)
+	require.Len(t, described.Reservations, 1)
+	require.Equal(t, ec2.InstanceStateNameRunning, *described.Reservations[0].Instances[0].State.Name)
+
+	_, err = client.TerminateInstances(&ec2.TerminateInstancesInput{
+		InstanceIds: aws.StringSlice([]string{instanceID}),
+	})
+	require.NoError(t, err)
+
+	described, err = client.DescribeInstances(&ec2.DescribeInstancesInput{
+		InstanceIds: aws.StringSlice([]string{instanceID}),
+	})
+	require.NoError(t, err)
+	require.Equal(t, ec2.InstanceStateNameTerminated, *described.Reservations[0].Instances[0].State.Name)
+}
+
+// TestClientSecurityGroupIngressAndDeletion verifies that ingress rules accumulate on a created
+// security group, and that deleting it (including deleting it twice) succeeds.
+func TestClientSecurityGroupIngressAndDeletion(t *testing.T) {
+	client := NewClient("test-infra")
+
+	created, err := client.CreateSecurityGroup(&ec2.CreateSecurityGroupInput{
+		GroupName: aws.String("windows-worker-sg"),
+	})
+	require.NoError(t, err)
+	sgID := *created.GroupId
+
+	_, err = client.AuthorizeSecurityGroupIngress(&ec2.AuthorizeSecurityGroupIngressInput{
+		GroupId: aws.String(sgID),
+		IpPermissions: []*ec2.IpPermission{
+			{IpProtocol: aws.String("tcp"), FromPort: aws.Int64(3389), ToPort: aws.Int64(3389)},
+		},
+	})
+	require.NoError(t, err)
+
+	described, err := client.DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{
+		GroupIds: aws.StringSlice([]string{sgID}),
+	})
+	require.NoError(t, err)
+	require.Len(t, described.SecurityGroups, 1)
+	require.Len(t, described.SecurityGroups[0].IpPermissions, 1)
+
+	_, err = client.DeleteSecurityGroup(&ec2.DeleteSecurityGroupInput{GroupId: aws.String(sgID)})
+	require.NoError(t, err)
+	_, err = client.DeleteSecurityGroup(&ec2.DeleteSecurityGroupInput{GroupId: aws.String(sgID)})
+	require.NoError(t, err)
+
+	_, err = client.DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{
+		GroupIds: aws.StringSlice([]string{sgID}),
+	})
+	require.Error(t, err, "describing a deleted security group by id should fail like real EC2 does")
+}
+
+// TestClientPrivateSubnetInstanceHasNoPublicIp verifies that an instance placed in the fake's
+// seeded private subnet is not given a public IP, while its route table is NAT-routed rather
+// than IGW-routed.
+func TestClientPrivateSubnetInstanceHasNoPublicIp(t *testing.T) {
+	client := NewClient("test-infra")
+
+	subnets, err := client.DescribeSubnets(&ec2.DescribeSubnetsInput{
+		Filters: []*ec2.Filter{
+			{Name: aws.String("tag:Name"), Values: aws.StringSlice([]string{"*private*"})},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, subnets.Subnets, 1)
+	privateSubnetID := *subnets.Subnets[0].SubnetId
+
+	reservation, err := client.RunInstances(&ec2.RunInstancesInput{
+		SubnetId: aws.String(privateSubnetID),
+		MinCount: aws.Int64(1),
+		MaxCount: aws.Int64(1),
+	})
+	require.NoError(t, err)
+	require.Nil(t, reservation.Instances[0].PublicIpAddress)
+
+	routeTables, err := client.DescribeRouteTables(&ec2.DescribeRouteTablesInput{
+		Filters: []*ec2.Filter{
+			{Name: aws.String("association.subnet-id"), Values: aws.StringSlice([]string{privateSubnetID})},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, routeTables.RouteTables, 1)
+	require.NotNil(t, routeTables.RouteTables[0].Routes[0].NatGatewayId)
+
+	nats, err := client.DescribeNatGateways(&ec2.DescribeNatGatewaysInput{
+		NatGatewayIds: []*string{routeTables.RouteTables[0].Routes[0].NatGatewayId},
+	})
+	require.NoError(t, err)
+	require.Len(t, nats.NatGateways, 1)
+}
+
+// TestClientDescribeImagesByNameFilter verifies that the seeded Windows AMI is findable via the
+// "name" filter, which is how the AWS provider looks up an AMI when no imageID is given.
+func TestClientDescribeImagesByNameFilter(t *testing.T) {
+	client := NewClient("test-infra")
+
+	images, err := client.DescribeImages(&ec2.DescribeImagesInput{
+		Filters: []*ec2.Filter{
+			{Name: aws.String("name"), Values: aws.StringSlice([]string{"Windows_Server-2019-English-Full-ContainersLatest*"})},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, images.Images, 1)
+	require.Contains(t, *images.Images[0].Name, "Windows_Server-2019-English-Full-ContainersLatest")
+}
+
+// TestClientDescribeSecurityGroupsByGroupNameFilter verifies that the seeded cluster worker
+// security group is findable via the "group-name" filter, which is how the AWS provider looks up
+// the cluster's worker SG, and that RunInstances records the security groups a caller attaches.
+func TestClientDescribeSecurityGroupsByGroupNameFilter(t *testing.T) {
+	client := NewClient("test-infra")
+
+	sgs, err := client.DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{
+		Filters: []*ec2.Filter{
+			{Name: aws.String("group-name"), Values: aws.StringSlice([]string{"test-infra-worker-sg"})},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, sgs.SecurityGroups, 1)
+	workerSgID := *sgs.SecurityGroups[0].GroupId
+
+	reservation, err := client.RunInstances(&ec2.RunInstancesInput{
+		MinCount:         aws.Int64(1),
+		MaxCount:         aws.Int64(1),
+		SecurityGroupIds: aws.StringSlice([]string{workerSgID}),
+	})
+	require.NoError(t, err)
+	require.Len(t, reservation.Instances[0].SecurityGroups, 1)
+	require.Equal(t, workerSgID, *reservation.Instances[0].SecurityGroups[0].GroupId)
+}
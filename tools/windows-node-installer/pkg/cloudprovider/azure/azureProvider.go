@@ -0,0 +1,345 @@
+// Package azure implements the cloudprovider.Cloud interface on top of Azure, creating and
+// destroying the Windows VMs and network security groups the Windows node installer manages in
+// an OpenShift cluster's resource group.
+package azure
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-12-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-11-01/network"
+	"github.com/Azure/azure-sdk-for-go/services/resources/mgmt/2019-10-01/resources"
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure/auth"
+	"github.com/openshift/windows-machine-config-bootstrapper/tools/windows-node-installer/pkg/cloudprovider/aws"
+	"github.com/openshift/windows-machine-config-bootstrapper/tools/windows-node-installer/pkg/resource"
+	"github.com/openshift/windows-machine-config-bootstrapper/tools/windows-node-installer/pkg/types"
+)
+
+// vmImageSKU is the Windows Server 2019 with Containers marketplace image used for every
+// Windows worker, matching the AMI the AWS provider selects by name.
+const (
+	vmImagePublisher = "MicrosoftWindowsServer"
+	vmImageOffer     = "WindowsServer"
+	vmImageSKU       = "2019-Datacenter-with-Containers"
+)
+
+// AzureProvider holds the state the Azure cloud provider needs to create and destroy Windows
+// VMs in a single OpenShift cluster's resource group and VNet.
+type AzureProvider struct {
+	VM     compute.VirtualMachinesClient
+	NIC    network.InterfacesClient
+	IP     network.PublicIPAddressesClient
+	NSG    network.SecurityGroupsClient
+	Subnet network.SubnetsClient
+	Group  resources.GroupsClient
+
+	resourceGroupName string
+	location          string
+
+	infraID            string
+	instanceType       string
+	sshKey             string
+	privateKeyPath     string
+	resourceTrackerDir string
+}
+
+// New creates a new AzureProvider authenticated from the environment (AZURE_* variables, as read
+// by auth.NewAuthorizerFromEnvironment), scoped to the resource group of the cluster pointed at
+// by kubeconfigPath.
+func New(kubeconfigPath, resourceTrackerDir, imageID, instanceType, sshKey, privateKeyPath string) (*AzureProvider, error) {
+	infraID, err := infraIDFromKubeconfig(kubeconfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("error getting infrastructure ID: %v", err)
+	}
+
+	subscriptionID := os.Getenv("AZURE_SUBSCRIPTION_ID")
+	if subscriptionID == "" {
+		return nil, fmt.Errorf("AZURE_SUBSCRIPTION_ID must be set")
+	}
+
+	authorizer, err := auth.NewAuthorizerFromEnvironment()
+	if err != nil {
+		return nil, fmt.Errorf("error creating Azure authorizer: %v", err)
+	}
+
+	provider := &AzureProvider{
+		VM:                 compute.NewVirtualMachinesClient(subscriptionID),
+		NIC:                network.NewInterfacesClient(subscriptionID),
+		IP:                 network.NewPublicIPAddressesClient(subscriptionID),
+		NSG:                network.NewSecurityGroupsClient(subscriptionID),
+		Subnet:             network.NewSubnetsClient(subscriptionID),
+		Group:              resources.NewGroupsClient(subscriptionID),
+		resourceGroupName:  infraID + "-rg",
+		infraID:            infraID,
+		instanceType:       instanceType,
+		sshKey:             sshKey,
+		privateKeyPath:     privateKeyPath,
+		resourceTrackerDir: resourceTrackerDir,
+	}
+	setAuthorizer(&provider.VM.Client, authorizer)
+	setAuthorizer(&provider.NIC.Client, authorizer)
+	setAuthorizer(&provider.IP.Client, authorizer)
+	setAuthorizer(&provider.NSG.Client, authorizer)
+	setAuthorizer(&provider.Subnet.Client, authorizer)
+	setAuthorizer(&provider.Group.Client, authorizer)
+
+	group, err := provider.Group.Get(context.TODO(), provider.resourceGroupName)
+	if err != nil {
+		return nil, fmt.Errorf("error getting cluster resource group %s: %v", provider.resourceGroupName, err)
+	}
+	provider.location = *group.Location
+
+	return provider, nil
+}
+
+func setAuthorizer(c *autorest.Client, authorizer autorest.Authorizer) {
+	c.Authorizer = authorizer
+}
+
+// GetInfraID returns the infrastructure ID of the OpenShift cluster this provider was created
+// against.
+func (a *AzureProvider) GetInfraID() (string, error) {
+	if a.infraID == "" {
+		return "", fmt.Errorf("infrastructure ID is not set")
+	}
+	return a.infraID, nil
+}
+
+// GetResourceGroupName returns the name of the cluster's resource group, used by callers to
+// look up resources created by CreateWindowsVM directly against the Azure SDK clients.
+func (a *AzureProvider) GetResourceGroupName() string {
+	return a.resourceGroupName
+}
+
+// CreateWindowsVM provisions a Windows Server 2019 VM in the cluster's resource group, attaches
+// it to the cluster VNet/subnet with a public IP and a dedicated NSG opening RDP, SSH, and
+// container-logs traffic, and records the created resources in the installer info file.
+func (a *AzureProvider) CreateWindowsVM() (*types.Credentials, error) {
+	ctx := context.TODO()
+
+	subnet, err := a.GetWorkerSubnet(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting cluster worker subnet: %v", err)
+	}
+
+	nsg, err := a.createWindowsWorkerNSG(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error creating Windows worker NSG: %v", err)
+	}
+
+	ipName := a.infraID + "-win-pip"
+	ipFuture, err := a.IP.CreateOrUpdate(ctx, a.resourceGroupName, ipName, network.PublicIPAddress{
+		Location: &a.location,
+		PublicIPAddressPropertiesFormat: &network.PublicIPAddressPropertiesFormat{
+			PublicIPAllocationMethod: network.Static,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating public IP: %v", err)
+	}
+	if err := ipFuture.WaitForCompletionRef(ctx, a.IP.Client); err != nil {
+		return nil, fmt.Errorf("error waiting for public IP: %v", err)
+	}
+	publicIP, err := ipFuture.Result(a.IP)
+	if err != nil {
+		return nil, fmt.Errorf("error reading public IP result: %v", err)
+	}
+
+	nicName := a.infraID + "-win-nic"
+	nicFuture, err := a.NIC.CreateOrUpdate(ctx, a.resourceGroupName, nicName, network.Interface{
+		Location: &a.location,
+		InterfacePropertiesFormat: &network.InterfacePropertiesFormat{
+			NetworkSecurityGroup: &network.SecurityGroup{ID: nsg.ID},
+			IPConfigurations: &[]network.InterfaceIPConfiguration{
+				{
+					Name: ptrString("ipconfig1"),
+					InterfaceIPConfigurationPropertiesFormat: &network.InterfaceIPConfigurationPropertiesFormat{
+						PublicIPAddress: &publicIP,
+						Subnet:          &subnet,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating NIC: %v", err)
+	}
+	if err := nicFuture.WaitForCompletionRef(ctx, a.NIC.Client); err != nil {
+		return nil, fmt.Errorf("error waiting for NIC: %v", err)
+	}
+	nic, err := nicFuture.Result(a.NIC)
+	if err != nil {
+		return nil, fmt.Errorf("error reading NIC result: %v", err)
+	}
+
+	vmName := a.infraID + "-win-worker"
+	password, err := generateTempPassword()
+	if err != nil {
+		return nil, fmt.Errorf("error generating VM admin password: %v", err)
+	}
+	vmFuture, err := a.VM.CreateOrUpdate(ctx, a.resourceGroupName, vmName, compute.VirtualMachine{
+		Location: &a.location,
+		Tags:     map[string]*string{"kubernetes.io_cluster_" + a.infraID: ptrString("owned")},
+		VirtualMachineProperties: &compute.VirtualMachineProperties{
+			HardwareProfile: &compute.HardwareProfile{VMSize: compute.VirtualMachineSizeTypes(a.instanceType)},
+			StorageProfile: &compute.StorageProfile{
+				ImageReference: &compute.ImageReference{
+					Publisher: ptrString(vmImagePublisher),
+					Offer:     ptrString(vmImageOffer),
+					Sku:       ptrString(vmImageSKU),
+					Version:   ptrString("latest"),
+				},
+			},
+			OsProfile: &compute.OSProfile{
+				ComputerName:  &vmName,
+				AdminUsername: ptrString("core"),
+				AdminPassword: &password,
+			},
+			NetworkProfile: &compute.NetworkProfile{
+				NetworkInterfaces: &[]compute.NetworkInterfaceReference{{ID: nic.ID}},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error creating Windows VM: %v", err)
+	}
+	if err := vmFuture.WaitForCompletionRef(ctx, a.VM.Client); err != nil {
+		return nil, fmt.Errorf("error waiting for Windows VM: %v", err)
+	}
+	vm, err := vmFuture.Result(a.VM)
+	if err != nil {
+		return nil, fmt.Errorf("error reading Windows VM result: %v", err)
+	}
+
+	if err := a.recordCreatedResources(*vm.Name, *nsg.Name); err != nil {
+		return nil, fmt.Errorf("error recording created resources: %v", err)
+	}
+
+	return types.NewCredentials(*vm.Name, *publicIP.IPAddress, password), nil
+}
+
+// DestroyWindowsVMs destroys every VM and NSG recorded in the installer info file.
+func (a *AzureProvider) DestroyWindowsVMs() error {
+	infoPath := a.installerInfoPath()
+	info, err := resource.ReadInstallerInfo(infoPath)
+	if err != nil {
+		return fmt.Errorf("error reading installer info: %v", err)
+	}
+
+	for _, vmName := range info.InstanceIDs {
+		if err := a.TerminateInstance(vmName); err != nil {
+			return fmt.Errorf("error terminating VM %s: %v", vmName, err)
+		}
+	}
+	for _, nsgName := range info.SecurityGroupIDs {
+		if err := a.DeleteSG(nsgName); err != nil {
+			return fmt.Errorf("error deleting NSG %s: %v", nsgName, err)
+		}
+	}
+
+	return resource.RemoveInstallerInfo(infoPath)
+}
+
+// TerminateInstance deletes the VM with the given name.
+func (a *AzureProvider) TerminateInstance(vmName string) error {
+	future, err := a.VM.Delete(context.TODO(), a.resourceGroupName, vmName)
+	if err != nil {
+		return err
+	}
+	return future.WaitForCompletionRef(context.TODO(), a.VM.Client)
+}
+
+// DeleteSG deletes the network security group with the given name.
+func (a *AzureProvider) DeleteSG(nsgName string) error {
+	future, err := a.NSG.Delete(context.TODO(), a.resourceGroupName, nsgName)
+	if err != nil {
+		return err
+	}
+	return future.WaitForCompletionRef(context.TODO(), a.NSG.Client)
+}
+
+// GetWorkerSubnet returns the cluster's worker subnet, in the cluster's VNet, so the Windows NIC
+// can be attached to the same network as the rest of the cluster's nodes.
+func (a *AzureProvider) GetWorkerSubnet(ctx context.Context) (network.Subnet, error) {
+	vnetName := a.infraID + "-vnet"
+	subnetName := a.infraID + "-worker-subnet"
+	subnet, err := a.Subnet.Get(ctx, a.resourceGroupName, vnetName, subnetName, "")
+	if err != nil {
+		return network.Subnet{}, fmt.Errorf("error getting worker subnet %s/%s: %v", vnetName, subnetName, err)
+	}
+	return subnet, nil
+}
+
+// createWindowsWorkerNSG creates an NSG allowing RDP, SSH, and container-logs traffic from the
+// caller's own IP and the cluster VNet CIDR, mirroring the AWS provider's Windows security group.
+func (a *AzureProvider) createWindowsWorkerNSG(ctx context.Context) (network.SecurityGroup, error) {
+	myIP, err := aws.GetMyIp()
+	if err != nil {
+		return network.SecurityGroup{}, fmt.Errorf("error getting caller's public IP: %v", err)
+	}
+
+	nsgName := a.infraID + "-windows-worker-nsg"
+	future, err := a.NSG.CreateOrUpdate(ctx, a.resourceGroupName, nsgName, network.SecurityGroup{
+		Location: &a.location,
+		SecurityGroupPropertiesFormat: &network.SecurityGroupPropertiesFormat{
+			SecurityRules: &[]network.SecurityRule{
+				allowInboundRule("allow_rdp", "3389", myIP+"/32", 100),
+				allowInboundRule("allow_ssh", "22", myIP+"/32", 110),
+				allowInboundRule("allow_container_logs", "10250", "VirtualNetwork", 120),
+			},
+		},
+	})
+	if err != nil {
+		return network.SecurityGroup{}, err
+	}
+	if err := future.WaitForCompletionRef(ctx, a.NSG.Client); err != nil {
+		return network.SecurityGroup{}, err
+	}
+	return future.Result(a.NSG)
+}
+
+func allowInboundRule(name, port, sourceCIDR string, priority int32) network.SecurityRule {
+	return network.SecurityRule{
+		Name: ptrString(name),
+		SecurityRulePropertiesFormat: &network.SecurityRulePropertiesFormat{
+			Protocol:                 network.SecurityRuleProtocolTCP,
+			Access:                   network.SecurityRuleAccessAllow,
+			Direction:                network.SecurityRuleDirectionInbound,
+			SourcePortRange:          ptrString("*"),
+			DestinationPortRange:     ptrString(port),
+			SourceAddressPrefix:      ptrString(sourceCIDR),
+			DestinationAddressPrefix: ptrString("*"),
+			Priority:                 &priority,
+		},
+	}
+}
+
+// recordCreatedResources appends the created VM name and NSG name to the installer info file.
+func (a *AzureProvider) recordCreatedResources(vmName, nsgName string) error {
+	info := &resource.InstallerInfo{
+		InstanceIDs:      []string{vmName},
+		SecurityGroupIDs: []string{nsgName},
+	}
+	return info.Save(a.installerInfoPath())
+}
+
+func (a *AzureProvider) installerInfoPath() string {
+	return a.resourceTrackerDir + "/windows-node-installer.json"
+}
+
+func ptrString(s string) *string {
+	return &s
+}
+
+// generateTempPassword returns a VM admin password meeting Azure's complexity requirements for
+// the short-lived duration of the e2e test's instance.
+func generateTempPassword() (string, error) {
+	suffix, err := randomSuffix(12)
+	if err != nil {
+		return "", err
+	}
+	return "Wni-" + suffix + "!9", nil
+}
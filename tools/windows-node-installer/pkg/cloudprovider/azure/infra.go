@@ -0,0 +1,33 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+
+	configclient "github.com/openshift/client-go/config/clientset/versioned"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// infraIDFromKubeconfig returns the infrastructure ID of the cluster pointed at by
+// kubeconfigPath, as reported by the cluster's Infrastructure config object.
+func infraIDFromKubeconfig(kubeconfigPath string) (string, error) {
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	if err != nil {
+		return "", fmt.Errorf("error building kubeconfig: %v", err)
+	}
+
+	client, err := configclient.NewForConfig(config)
+	if err != nil {
+		return "", fmt.Errorf("error creating config client: %v", err)
+	}
+
+	infra, err := client.ConfigV1().Infrastructures().Get(context.TODO(), "cluster", metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("error getting infrastructure resource: %v", err)
+	}
+	if infra.Status.InfrastructureName == "" {
+		return "", fmt.Errorf("infrastructure resource has no infrastructure name")
+	}
+	return infra.Status.InfrastructureName, nil
+}
@@ -0,0 +1,23 @@
+package azure
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+const passwordChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// randomSuffix returns a cryptographically random alphanumeric string of the given length, used
+// to pad the temporary admin password generated for each Windows VM.
+func randomSuffix(length int) (string, error) {
+	suffix := make([]byte, length)
+	for i := range suffix {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(passwordChars))))
+		if err != nil {
+			return "", fmt.Errorf("error generating random password suffix: %v", err)
+		}
+		suffix[i] = passwordChars[n.Int64()]
+	}
+	return string(suffix), nil
+}
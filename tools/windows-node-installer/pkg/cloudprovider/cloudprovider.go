@@ -0,0 +1,56 @@
+package cloudprovider
+
+import (
+	"fmt"
+
+	awscp "github.com/openshift/windows-machine-config-bootstrapper/tools/windows-node-installer/pkg/cloudprovider/aws"
+	azurecp "github.com/openshift/windows-machine-config-bootstrapper/tools/windows-node-installer/pkg/cloudprovider/azure"
+	"github.com/openshift/windows-machine-config-bootstrapper/tools/windows-node-installer/pkg/types"
+)
+
+// Cloud is the interface that every cloud provider implementation must satisfy so that the
+// Windows node installer can create and destroy Windows instances without knowing which
+// infrastructure provider backs the OpenShift cluster.
+type Cloud interface {
+	// CreateWindowsVM creates a Windows instance and all the resources it depends on, and
+	// returns the credentials needed to log into it.
+	CreateWindowsVM() (*types.Credentials, error)
+	// DestroyWindowsVMs destroys all the Windows instances and dependent resources recorded
+	// in the installer info file.
+	DestroyWindowsVMs() error
+	// TerminateInstance terminates a single instance by id.
+	TerminateInstance(instanceID string) error
+	// DeleteSG deletes a single security group by id.
+	DeleteSG(sgID string) error
+	// GetInfraID returns the infrastructure id of the OpenShift cluster the installer is
+	// pointed at.
+	GetInfraID() (string, error)
+}
+
+// CloudProviderFactory creates a provider for the Cloud interface based on the platform the
+// given kubeconfig points at. An explicit platformOverride (e.g. from a --platform flag) may be
+// passed to skip inferring the platform from the kubeconfig, which is useful when the caller
+// already knows it and wants to avoid the extra API call.
+func CloudProviderFactory(kubeconfigPath, credentialsPath, credentialAccountID, resourceTrackerDir,
+	imageID, instanceType, sshKeyPair, privateKeyPath string, platformOverride ...string) (Cloud, error) {
+	platform := ""
+	if len(platformOverride) > 0 && platformOverride[0] != "" {
+		platform = platformOverride[0]
+	} else {
+		var err error
+		platform, err = getPlatform(kubeconfigPath)
+		if err != nil {
+			return nil, fmt.Errorf("error getting cluster platform from kubeconfig: %v", err)
+		}
+	}
+
+	switch platform {
+	case "AWS":
+		return awscp.New(kubeconfigPath, credentialsPath, credentialAccountID, resourceTrackerDir,
+			imageID, instanceType, sshKeyPair, privateKeyPath)
+	case "Azure":
+		return azurecp.New(kubeconfigPath, resourceTrackerDir, imageID, instanceType, sshKeyPair, privateKeyPath)
+	default:
+		return nil, fmt.Errorf("unsupported cloud provider platform: %s", platform)
+	}
+}
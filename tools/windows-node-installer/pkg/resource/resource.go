@@ -0,0 +1,53 @@
+package resource
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// InstallerInfo contains the IDs of resources created by the Windows node installer so that a
+// later teardown can find and remove them without relying on tags or naming conventions alone.
+type InstallerInfo struct {
+	// SecurityGroupIDs contains the IDs of the security groups created for the Windows instances.
+	SecurityGroupIDs []string `json:"securityGroupIDs"`
+	// InstanceIDs contains the IDs of the Windows instances created.
+	InstanceIDs []string `json:"instanceIDs"`
+	// PrivateOnly records whether the instances were created without a public IP in a private
+	// subnet, so that teardown and e2e assertions can branch on the mode the instance was
+	// created in.
+	PrivateOnly bool `json:"privateOnly,omitempty"`
+}
+
+// ReadInstallerInfo reads and returns the InstallerInfo recorded at the given file path.
+func ReadInstallerInfo(filePath string) (*InstallerInfo, error) {
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %v", filePath, err)
+	}
+
+	info := &InstallerInfo{}
+	if err := json.Unmarshal(content, info); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s: %v", filePath, err)
+	}
+	return info, nil
+}
+
+// Save writes the InstallerInfo out to the given file path as json, overwriting any existing
+// file.
+func (info *InstallerInfo) Save(filePath string) error {
+	content, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("failed to marshal installer info: %v", err)
+	}
+	return ioutil.WriteFile(filePath, content, 0644)
+}
+
+// RemoveInstallerInfo deletes the file at the given path, if it exists.
+func RemoveInstallerInfo(filePath string) error {
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return nil
+	}
+	return os.Remove(filePath)
+}
@@ -0,0 +1,49 @@
+// Package retry provides a small polling/backoff helper used in place of hand-rolled
+// `for i := 0; i < n; i++ { ...; time.Sleep(d) }` loops.
+package retry
+
+import "time"
+
+// Strategy describes how long and how often to retry an operation. It mirrors the shape of a
+// fixed-interval polling loop: keep trying for up to Total, sleeping Delay between attempts, but
+// never give up before Min attempts have been made even if Total has already elapsed.
+type Strategy struct {
+	// Total is the maximum time to keep retrying for.
+	Total time.Duration
+	// Delay is how long to sleep between attempts.
+	Delay time.Duration
+	// Min is the minimum number of attempts to make before giving up, regardless of Total. A
+	// zero value means Total is the only bound.
+	Min int
+}
+
+// Attempt tracks progress through a single Start()/Next() retry loop.
+type Attempt struct {
+	strategy Strategy
+	count    int
+	end      time.Time
+}
+
+// Start begins a new retry loop for the Strategy.
+func (s Strategy) Start() *Attempt {
+	return &Attempt{strategy: s}
+}
+
+// Next reports whether another attempt should be made, sleeping for the Strategy's Delay before
+// returning true if this isn't the first call. It returns false once both the Min attempt budget
+// has been used and the Total deadline has passed.
+func (a *Attempt) Next() bool {
+	now := time.Now()
+	if a.count == 0 {
+		a.end = now.Add(a.strategy.Total)
+		a.count++
+		return true
+	}
+
+	if a.count < a.strategy.Min || now.Before(a.end) {
+		time.Sleep(a.strategy.Delay)
+		a.count++
+		return true
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package retry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAttemptRespectsMin verifies that Next() keeps returning true until Min attempts have been
+// made, even though Total has already elapsed.
+func TestAttemptRespectsMin(t *testing.T) {
+	strategy := Strategy{Total: time.Nanosecond, Delay: time.Millisecond, Min: 3}
+	attempt := strategy.Start()
+
+	count := 0
+	for attempt.Next() {
+		count++
+	}
+	assert.Equal(t, 3, count)
+}
+
+// TestAttemptRespectsTotal verifies that Next() keeps returning true for at least Total, even
+// with no Min set.
+func TestAttemptRespectsTotal(t *testing.T) {
+	strategy := Strategy{Total: 50 * time.Millisecond, Delay: 10 * time.Millisecond}
+	attempt := strategy.Start()
+
+	start := time.Now()
+	count := 0
+	for attempt.Next() {
+		count++
+	}
+	assert.GreaterOrEqual(t, count, 2)
+	assert.GreaterOrEqual(t, time.Since(start), strategy.Total)
+}
@@ -0,0 +1,60 @@
+package types
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// BootstrapConfig describes the first-boot bootstrapping to perform on a created Windows
+// instance, so it can join the cluster without anyone SSHing in afterwards.
+type BootstrapConfig struct {
+	// KubeletArgs are extra flags appended to the kubelet service's start command.
+	KubeletArgs []string
+	// CABundle is the PEM-encoded CA bundle the kubelet uses to verify the API server.
+	CABundle []byte
+	// IgnitionURL is a pointer to a full ignition/bootstrap config the instance should fetch
+	// and execute on first boot. Mutually exclusive with IgnitionInline.
+	IgnitionURL string
+	// IgnitionInline is the bootstrap config's content, used instead of fetching IgnitionURL
+	// when the caller wants to avoid standing up a pointer endpoint.
+	IgnitionInline []byte
+}
+
+// UserDataScript renders the BootstrapConfig as a Windows EC2Launch user-data script: the
+// `<powershell>`/`<persist>true</persist>` wrapper EC2Launch runs on every boot, which fetches
+// or writes the ignition payload and invokes it to install the kubelet, write the kubeconfig,
+// join the cluster, and open the container-logs firewall rule.
+func (b *BootstrapConfig) UserDataScript() (string, error) {
+	if b.IgnitionURL == "" && len(b.IgnitionInline) == 0 {
+		return "", fmt.Errorf("bootstrap config must set one of IgnitionURL or IgnitionInline")
+	}
+
+	// Multi-line content (PEM bundles, ignition JSON) is base64-encoded before being embedded in
+	// the script: %q escapes newlines as the two characters `\n`, which a PowerShell
+	// double-quoted string does not turn back into a real line break, corrupting the file it
+	// writes on the instance.
+	fetch := fmt.Sprintf("Invoke-WebRequest -Uri %q -OutFile C:\\Windows\\Temp\\bootstrap.ign", b.IgnitionURL)
+	if len(b.IgnitionInline) > 0 {
+		fetch = fmt.Sprintf(
+			"[System.IO.File]::WriteAllBytes(\"C:\\Windows\\Temp\\bootstrap.ign\", [System.Convert]::FromBase64String(%q))",
+			base64.StdEncoding.EncodeToString(b.IgnitionInline))
+	}
+
+	writeCABundle := ""
+	caBundleArg := ""
+	if len(b.CABundle) > 0 {
+		writeCABundle = fmt.Sprintf(
+			"[System.IO.File]::WriteAllBytes(\"C:\\Windows\\Temp\\ca.crt\", [System.Convert]::FromBase64String(%q))\n",
+			base64.StdEncoding.EncodeToString(b.CABundle))
+		caBundleArg = ` --ca-bundle C:\Windows\Temp\ca.crt`
+	}
+
+	return fmt.Sprintf(`<powershell>
+%s%s
+& "C:\Windows\Temp\wmcb.exe" bootstrap --ignition-file C:\Windows\Temp\bootstrap.ign%s --kubelet-args %q
+New-NetFirewallRule -DisplayName "ContainerLogsPort" -LocalPort 10250 -Protocol TCP -Action Allow
+</powershell>
+<persist>true</persist>
+`, writeCABundle, fetch, caBundleArg, strings.Join(b.KubeletArgs, " ")), nil
+}
@@ -0,0 +1,29 @@
+package types
+
+// Credentials stores login information for a created Windows instance so that callers can
+// SSH or RDP into it after creation.
+type Credentials struct {
+	instanceId string
+	ipAddress  string
+	password   string
+}
+
+// NewCredentials returns a Credentials object that contains login information for an instance.
+func NewCredentials(instanceId, ipAddress, password string) *Credentials {
+	return &Credentials{instanceId: instanceId, ipAddress: ipAddress, password: password}
+}
+
+// GetInstanceId returns the instance id of the credentials object.
+func (c *Credentials) GetInstanceId() string {
+	return c.instanceId
+}
+
+// GetIPAddress returns the ip address of the credentials object.
+func (c *Credentials) GetIPAddress() string {
+	return c.ipAddress
+}
+
+// GetPassword returns the password of the credentials object.
+func (c *Credentials) GetPassword() string {
+	return c.password
+}
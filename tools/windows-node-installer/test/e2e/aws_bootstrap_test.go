@@ -0,0 +1,205 @@
+package e2e
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	awscp "github.com/openshift/windows-machine-config-bootstrapper/tools/windows-node-installer/pkg/cloudprovider/aws"
+	"github.com/openshift/windows-machine-config-bootstrapper/tools/windows-node-installer/pkg/resource"
+	"github.com/openshift/windows-machine-config-bootstrapper/tools/windows-node-installer/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// nodeRegistrationTimeout bounds how long testBootstrapInstanceJoinsCluster waits for the
+// bootstrapped instance to show up as a cluster Node.
+const nodeRegistrationTimeout = 15 * time.Minute
+
+var (
+	// bootstrapAwsProvider, createdBootstrapInstanceID, and createdBootstrapSgID are dedicated
+	// to the bootstrap e2e suite so it doesn't clobber the SSH-based suite's tracked resources.
+	bootstrapAwsProvider       = &awscp.AwsProvider{}
+	createdBootstrapInstanceID = ""
+	createdBootstrapSgID       = ""
+	submittedUserData          = ""
+)
+
+// TestAwsBootstrapE2eSerial runs the e2e suite for UserData-bootstrapped Windows instances: it
+// creates an instance with a BootstrapConfig set, skips SSH entirely, and asserts that the node
+// registers with the API server on its own.
+func TestAwsBootstrapE2eSerial(t *testing.T) {
+	err := awsBootstrapSetup()
+	if err != nil {
+		tdErr := tearDownBootstrapInstance()
+		if tdErr != nil {
+			t.Logf("error with test teardown: %s", tdErr)
+		}
+		t.Fatal(err)
+	}
+
+	t.Run("test UserData matches what was submitted", testBootstrapUserDataMatches)
+	t.Run("test instance registers with the API server without SSH", testBootstrapInstanceJoinsCluster)
+
+	err = tearDownBootstrapInstance()
+	if err != nil {
+		t.Logf("error with test teardown: %s", err)
+	}
+}
+
+// awsBootstrapSetup creates a Windows instance with a BootstrapConfig set, instead of relying on
+// SSH credentials.
+func awsBootstrapSetup() error {
+	provider, err := awscp.New(kubeconfig, awscredentials, "default", artifactDir, imageID,
+		instanceType, sshKey, privateKeyPath)
+	if err != nil {
+		return fmt.Errorf("error creating AWS provider: %s", err)
+	}
+
+	caBundle, err := clusterCABundle()
+	if err != nil {
+		return fmt.Errorf("error getting cluster CA bundle: %s", err)
+	}
+
+	ignitionURL, err := clusterAPIIntURL()
+	if err != nil {
+		return fmt.Errorf("error getting cluster api-int URL: %s", err)
+	}
+
+	bootstrapConfig := &types.BootstrapConfig{
+		KubeletArgs: []string{"--node-labels=node.openshift.io/os_id=Windows"},
+		CABundle:    caBundle,
+		IgnitionURL: ignitionURL,
+	}
+	submittedUserData, err = bootstrapConfig.UserDataScript()
+	if err != nil {
+		return fmt.Errorf("error rendering bootstrap user data: %s", err)
+	}
+
+	provider.SetBootstrapConfig(bootstrapConfig)
+	bootstrapAwsProvider = provider
+
+	if _, err := bootstrapAwsProvider.CreateWindowsVM(); err != nil {
+		return fmt.Errorf("error creating bootstrapped Windows instance: %s", err)
+	}
+
+	info, err := resource.ReadInstallerInfo(artifactDir + "/" + "windows-node-installer.json")
+	if err != nil {
+		return fmt.Errorf("error reading from windows-node-installer.json file: %s", err)
+	}
+	if len(info.InstanceIDs) != 1 || info.InstanceIDs[0] == "" {
+		return fmt.Errorf("expected one instance but found %v", info.InstanceIDs)
+	}
+	if len(info.SecurityGroupIDs) != 1 || info.SecurityGroupIDs[0] == "" {
+		return fmt.Errorf("expected one security group but found %v", info.SecurityGroupIDs)
+	}
+	createdBootstrapInstanceID = info.InstanceIDs[0]
+	createdBootstrapSgID = info.SecurityGroupIDs[0]
+	return nil
+}
+
+// tearDownBootstrapInstance removes the lingering bootstrapped instance and security group.
+func tearDownBootstrapInstance() error {
+	if createdBootstrapInstanceID != "" {
+		if err := bootstrapAwsProvider.TerminateInstance(createdBootstrapInstanceID); err != nil {
+			return fmt.Errorf("error terminating bootstrapped instance during teardown, %v", err)
+		}
+	}
+	createdBootstrapInstanceID = ""
+
+	if createdBootstrapSgID != "" {
+		if err := bootstrapAwsProvider.DeleteSG(createdBootstrapSgID); err != nil {
+			return fmt.Errorf("error deleting bootstrapped security group during teardown, %v", err)
+		}
+	}
+	createdBootstrapSgID = ""
+	return nil
+}
+
+// testBootstrapUserDataMatches asserts that the UserData recorded on the created instance is the
+// same base64 payload CreateWindowsVM submitted.
+func testBootstrapUserDataMatches(t *testing.T) {
+	attr, err := bootstrapAwsProvider.EC2.DescribeInstanceAttribute(&ec2.DescribeInstanceAttributeInput{
+		InstanceId: aws.String(createdBootstrapInstanceID),
+		Attribute:  aws.String(ec2.InstanceAttributeNameUserData),
+	})
+	require.NoError(t, err, "could not describe UserData for instance %s", createdBootstrapInstanceID)
+	require.NotNil(t, attr.UserData)
+
+	decoded, err := base64.StdEncoding.DecodeString(*attr.UserData.Value)
+	require.NoError(t, err, "instance UserData is not valid base64")
+	assert.Equal(t, submittedUserData, string(decoded))
+}
+
+// testBootstrapInstanceJoinsCluster asserts that the instance registers as a cluster Node within
+// nodeRegistrationTimeout, without the test ever SSHing into it.
+func testBootstrapInstanceJoinsCluster(t *testing.T) {
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	require.NoError(t, err, "error building kubeconfig")
+	client, err := kubernetes.NewForConfig(config)
+	require.NoError(t, err, "error creating kube client")
+
+	deadline := time.Now().Add(nodeRegistrationTimeout)
+	for time.Now().Before(deadline) {
+		nodes, err := client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+		if err == nil {
+			for _, node := range nodes.Items {
+				if strings.Contains(node.Spec.ProviderID, createdBootstrapInstanceID) {
+					return
+				}
+			}
+		}
+		time.Sleep(30 * time.Second)
+	}
+	assert.Fail(t, "instance did not register as a cluster Node within %s", nodeRegistrationTimeout)
+}
+
+// clusterCABundle returns the CA bundle used to verify the API server, read from the local
+// kubeconfig so the bootstrap config can be generated without an extra API call.
+func clusterCABundle() ([]byte, error) {
+	config, err := clientcmd.LoadFromFile(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	for _, cluster := range config.Clusters {
+		if len(cluster.CertificateAuthorityData) > 0 {
+			return cluster.CertificateAuthorityData, nil
+		}
+	}
+	return nil, fmt.Errorf("no cluster CA data found in kubeconfig")
+}
+
+// clusterAPIIntURL returns the cluster's internal machine-config-server worker ignition URL,
+// derived from the kubeconfig's cluster Server URL (https://api.<cluster-domain>:6443) by
+// swapping the "api." host prefix for "api-int." and pointing at the MCS port, rather than from
+// the kubeconfig's file path.
+func clusterAPIIntURL() (string, error) {
+	config, err := clientcmd.LoadFromFile(kubeconfig)
+	if err != nil {
+		return "", err
+	}
+	for _, cluster := range config.Clusters {
+		if cluster.Server == "" {
+			continue
+		}
+		serverURL, err := url.Parse(cluster.Server)
+		if err != nil {
+			return "", fmt.Errorf("error parsing cluster server URL %q: %v", cluster.Server, err)
+		}
+		host := serverURL.Hostname()
+		if !strings.HasPrefix(host, "api.") {
+			return "", fmt.Errorf("cluster server host %q does not start with api.", host)
+		}
+		return "https://api-int." + strings.TrimPrefix(host, "api.") + ":22623/config/worker", nil
+	}
+	return "", fmt.Errorf("no cluster found in kubeconfig")
+}
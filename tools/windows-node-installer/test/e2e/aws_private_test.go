@@ -0,0 +1,147 @@
+package e2e
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	awscp "github.com/openshift/windows-machine-config-bootstrapper/tools/windows-node-installer/pkg/cloudprovider/aws"
+	"github.com/openshift/windows-machine-config-bootstrapper/tools/windows-node-installer/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	// privateAwsProvider is a second AwsProvider dedicated to the private-only e2e suite, kept
+	// separate from awsProvider so the two suites don't clobber each other's tracked resources.
+	privateAwsProvider = &awscp.AwsProvider{}
+
+	createdPrivateInstanceID = ""
+	createdPrivateSgID       = ""
+)
+
+// TestAwsPrivateE2eSerial runs the e2e suite for PrivateOnly Windows instances: it creates an
+// instance with no public IP in a private subnet, checks that mode's properties, then destroys
+// it.
+func TestAwsPrivateE2eSerial(t *testing.T) {
+	err := awsPrivateSetup()
+	if err != nil {
+		tdErr := tearDownPrivateInstance()
+		if tdErr != nil {
+			t.Logf("error with test teardown: %s", tdErr)
+		}
+		t.Fatal(err)
+	}
+
+	t.Run("test instance has no public IP and is in a private subnet", testInstanceHasPrivateSubnet)
+	t.Run("test Windows security group has no myIp/32 rule", testPrivateSgHasNoMyIpRule)
+
+	err = tearDownPrivateInstance()
+	if err != nil {
+		t.Logf("error with test teardown: %s", err)
+	}
+}
+
+// awsPrivateSetup creates a PrivateOnly Windows instance and updates the package's private
+// instance/SG globals.
+func awsPrivateSetup() error {
+	provider, err := awscp.New(kubeconfig, awscredentials, "default", artifactDir, imageID,
+		instanceType, sshKey, privateKeyPath)
+	if err != nil {
+		return fmt.Errorf("error creating AWS provider: %s", err)
+	}
+	provider.SetPrivateOnly(true)
+	privateAwsProvider = provider
+
+	if _, err := privateAwsProvider.CreateWindowsVM(); err != nil {
+		return fmt.Errorf("error creating private Windows instance: %s", err)
+	}
+
+	info, err := resource.ReadInstallerInfo(artifactDir + "/" + "windows-node-installer.json")
+	if err != nil {
+		return fmt.Errorf("error reading from windows-node-installer.json file: %s", err)
+	}
+	if !info.PrivateOnly {
+		return fmt.Errorf("expected windows-node-installer.json to record privateOnly mode")
+	}
+	if len(info.InstanceIDs) != 1 || info.InstanceIDs[0] == "" {
+		return fmt.Errorf("expected one instance but found %v", info.InstanceIDs)
+	}
+	if len(info.SecurityGroupIDs) != 1 || info.SecurityGroupIDs[0] == "" {
+		return fmt.Errorf("expected one security group but found %v", info.SecurityGroupIDs)
+	}
+	createdPrivateInstanceID = info.InstanceIDs[0]
+	createdPrivateSgID = info.SecurityGroupIDs[0]
+	return nil
+}
+
+// tearDownPrivateInstance removes the lingering private instance and security group.
+func tearDownPrivateInstance() error {
+	if createdPrivateInstanceID != "" {
+		if err := privateAwsProvider.TerminateInstance(createdPrivateInstanceID); err != nil {
+			return fmt.Errorf("error terminating private instance during teardown, %v", err)
+		}
+	}
+	createdPrivateInstanceID = ""
+
+	if createdPrivateSgID != "" {
+		if err := privateAwsProvider.DeleteSG(createdPrivateSgID); err != nil {
+			return fmt.Errorf("error deleting private security group during teardown, %v", err)
+		}
+	}
+	createdPrivateSgID = ""
+	return nil
+}
+
+// testInstanceHasPrivateSubnet asserts that the instance has no public IP and is attached to a
+// subnet that is routed via a NAT gateway rather than an internet gateway. This replaces
+// testInstanceHasPublicSubnetAndIp for PrivateOnly instances.
+func testInstanceHasPrivateSubnet(t *testing.T) {
+	instances, err := privateAwsProvider.EC2.DescribeInstances(&ec2.DescribeInstancesInput{
+		InstanceIds: aws.StringSlice([]string{createdPrivateInstanceID}),
+	})
+	require.NoError(t, err, "could not describe private instance %s", createdPrivateInstanceID)
+	require.Len(t, instances.Reservations, 1)
+	instance := instances.Reservations[0].Instances[0]
+
+	assert.Empty(t, instance.PublicIpAddress, "private instance unexpectedly has a public IP address")
+
+	routeTables, err := privateAwsProvider.EC2.DescribeRouteTables(&ec2.DescribeRouteTablesInput{
+		Filters: []*ec2.Filter{
+			{
+				Name:   aws.String("association.subnet-id"),
+				Values: []*string{instance.SubnetId},
+			},
+		},
+	})
+	require.NoError(t, err, "error finding route table for subnet %s", *instance.SubnetId)
+	require.Len(t, routeTables.RouteTables, 1)
+
+	for _, route := range routeTables.RouteTables[0].Routes {
+		if route.NatGatewayId != nil {
+			return
+		}
+	}
+	assert.Fail(t, "subnet associated with private instance is not routed via a NAT gateway")
+}
+
+// testPrivateSgHasNoMyIpRule asserts that the PrivateOnly Windows security group scopes ingress
+// to the VPC CIDR only, with no rule for the caller's own IP.
+func testPrivateSgHasNoMyIpRule(t *testing.T) {
+	myIP, err := awscp.GetMyIp()
+	require.NoError(t, err, "error getting caller's public IP")
+
+	sgs, err := privateAwsProvider.EC2.DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{
+		GroupIds: aws.StringSlice([]string{createdPrivateSgID}),
+	})
+	require.NoError(t, err)
+	require.Len(t, sgs.SecurityGroups, 1)
+
+	for _, perm := range sgs.SecurityGroups[0].IpPermissions {
+		for _, r := range perm.IpRanges {
+			assert.NotEqual(t, myIP+"/32", aws.StringValue(r.CidrIp),
+				"private security group unexpectedly scopes ingress to the caller's IP")
+		}
+	}
+}
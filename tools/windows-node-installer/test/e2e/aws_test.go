@@ -11,6 +11,8 @@ import (
 	"github.com/openshift/windows-machine-config-bootstrapper/tools/windows-node-installer/pkg/cloudprovider"
 	awscp "github.com/openshift/windows-machine-config-bootstrapper/tools/windows-node-installer/pkg/cloudprovider/aws"
 	"github.com/openshift/windows-machine-config-bootstrapper/tools/windows-node-installer/pkg/resource"
+	"github.com/openshift/windows-machine-config-bootstrapper/tools/windows-node-installer/pkg/retry"
+	"github.com/openshift/windows-machine-config-bootstrapper/tools/windows-node-installer/pkg/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -28,10 +30,22 @@ var (
 	instanceType = "m4.large"
 	sshKey       = "libra"
 
+	// pollStrategy bounds how long the suite polls for eventually-consistent AWS state (instance
+	// status, terminated state, security group deletion). WNI_E2E_RETRY_TOTAL and
+	// WNI_E2E_RETRY_DELAY let CI dial the timeouts up for slower regions.
+	pollStrategy = retry.Strategy{
+		Total: envDurationOrDefault("WNI_E2E_RETRY_TOTAL", 5*time.Minute),
+		Delay: envDurationOrDefault("WNI_E2E_RETRY_DELAY", 5*time.Second),
+		Min:   1,
+	}
+
 	// awsProvider is setup as a variable for both creating, destroying,
 	// and tear down Windows instance in case test fails in the middle.
 	awsProvider = &awscp.AwsProvider{}
 
+	// credentials holds the login info returned by CreateWindowsVM for the created instance.
+	credentials = &types.Credentials{}
+
 	// Set global variables for instance object, instance, security group,
 	// and infrastructure IDs so that once they are created,
 	// they will be used by all subsequent testing functions.
@@ -180,19 +194,37 @@ func setupWindowsInstanceWithResources() error {
 	return nil
 }
 
-// waitForStatusok waits for the instance to be okay.
+// envDurationOrDefault parses the duration in the named env var, falling back to def if it is
+// unset or invalid.
+func envDurationOrDefault(name string, def time.Duration) time.Duration {
+	if v := os.Getenv(name); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+	}
+	return def
+}
+
+// waitForStatusOk waits for the instance to be okay, retrying through the "not found yet" errors
+// AWS can return right after an instance is created.
 func waitForStatusOk(instanceId string) error {
-	for i := 0; i < retryCount; i++ {
+	var lastErr error
+	attempt := pollStrategy.Start()
+	for attempt.Next() {
 		instanceStatus, err := getInstanceStatus(instanceId)
 		if err != nil {
-			fmt.Errorf("failed to get the status of the instance: %v", err)
+			if !awscp.IsNotFoundError(err) {
+				return fmt.Errorf("failed to get the status of the instance: %v", err)
+			}
+			lastErr = err
+			continue
 		}
 		if instanceStatus == "ok" {
 			return nil
 		}
-		time.Sleep(retryInterval)
+		lastErr = fmt.Errorf("instance status is %q, not ok", instanceStatus)
 	}
-	return fmt.Errorf("failed to obtain the ok status")
+	return fmt.Errorf("failed to obtain the ok status: %v", lastErr)
 }
 
 // getInstanceStatus returns the status of the instance.
@@ -210,7 +242,7 @@ func getInstanceStatus(instanceId string) (string, error) {
 	}
 	result, err := ec2Svc.DescribeInstanceStatus(input)
 	if err != nil {
-		return "", fmt.Errorf("failed to DescribeInstanceStatus with error: %v", err)
+		return "", err
 	}
 	if result.InstanceStatuses == nil {
 		return "", fmt.Errorf("InstanceStatuses is nil")
@@ -422,30 +454,55 @@ func testInstanceIsAssociatedWithClusterWorkerIAM(t *testing.T) {
 	assert.Equal(t, *iamProfile.Arn, *createdInstance.IamInstanceProfile.Arn, "instance is not associated with worker IAM profile")
 }
 
-// destroyingWindowsInstance destroys Windows instance and updates the createdInstance global object.
+// destroyingWindowsInstance destroys Windows instance and waits for it to reach the terminated
+// state, updating the createdInstance global object. A NotFound error while polling is itself
+// treated as confirmation that the instance is gone.
 func destroyingWindowsInstance(t *testing.T) {
 	err := awsProvider.DestroyWindowsVMs()
 	require.NoError(t, err, "Error destroying Windows VMs")
 
-	createdInstance, err = getInstance(createdInstanceID)
+	attempt := pollStrategy.Start()
+	for attempt.Next() {
+		createdInstance, err = getInstance(createdInstanceID)
+		if err != nil {
+			if awscp.IsNotFoundError(err) {
+				return
+			}
+			continue
+		}
+		if *createdInstance.State.Name == ec2.InstanceStateNameTerminated {
+			return
+		}
+	}
 	require.NoError(t, err, "Error retrieving Windows VM")
-
 	assert.Equal(t, ec2.InstanceStateNameTerminated, *createdInstance.State.Name,
 		"instance is not in the terminated state")
 }
 
 // testSgIsDeleted asserts if a security group is deleted by checking whether the security group exist on AWS.
-// If delete is successful, the id in createdSgID is erased.
+// If delete is successful, the id in createdSgID is erased. An InvalidGroup.NotFound error while
+// polling is treated as confirmation that the security group is gone.
 func testSgIsDeleted(t *testing.T) {
-	sgs, err := awsProvider.EC2.DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{
-		GroupIds: aws.StringSlice([]string{createdSgID}),
-	})
-
-	if err == nil && len(sgs.SecurityGroups) > 0 {
-		assert.Fail(t, "security group is not deleted")
-	} else {
-		createdSgID = ""
+	var err error
+	attempt := pollStrategy.Start()
+	for attempt.Next() {
+		var sgs *ec2.DescribeSecurityGroupsOutput
+		sgs, err = awsProvider.EC2.DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{
+			GroupIds: aws.StringSlice([]string{createdSgID}),
+		})
+		if err != nil {
+			if awscp.IsNotFoundError(err) {
+				createdSgID = ""
+				return
+			}
+			continue
+		}
+		if len(sgs.SecurityGroups) == 0 {
+			createdSgID = ""
+			return
+		}
 	}
+	assert.Fail(t, fmt.Sprintf("security group is not deleted: %v", err))
 }
 
 // testInstallerJsonFileIsDeleted asserts that the windows-node-installer.json is deleted.
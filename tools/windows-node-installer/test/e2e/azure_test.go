@@ -0,0 +1,196 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/openshift/windows-machine-config-bootstrapper/tools/windows-node-installer/pkg/cloudprovider"
+	azurecp "github.com/openshift/windows-machine-config-bootstrapper/tools/windows-node-installer/pkg/cloudprovider/azure"
+	"github.com/openshift/windows-machine-config-bootstrapper/tools/windows-node-installer/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	// azureProvider is setup as a variable for both creating, destroying, and tearing down the
+	// Windows instance in case the test fails in the middle.
+	azureProvider = &azurecp.AzureProvider{}
+
+	createdAzureInstanceName = ""
+	createdAzureNsgName      = ""
+	azureInfraID             = ""
+)
+
+// TestAzureE2eSerial runs all e2e tests for the Azure implementation serially, mirroring
+// TestAwsE2eSerial: it creates the Windows VM, checks its properties, then destroys it and
+// checks that the dependent resources are deleted.
+func TestAzureE2eSerial(t *testing.T) {
+	err := azureSetup()
+	if err != nil {
+		tdErr := tearDownAzureInstance()
+		if tdErr != nil {
+			t.Logf("error with test teardown: %s", tdErr)
+		}
+		t.Fatal(err)
+	}
+
+	t.Run("test create Windows VM", testCreateAzureWindowsInstance)
+
+	t.Run("test destroy Windows VM", testDestroyAzureWindowsInstance)
+
+	// Make sure the instance is torn down in case the destroy fails
+	err = tearDownAzureInstance()
+	if err != nil {
+		t.Logf("error with test teardown: %s", err)
+	}
+}
+
+// testCreateAzureWindowsInstance tests the creation of a Windows VM and checks its properties.
+func testCreateAzureWindowsInstance(t *testing.T) {
+	t.Run("test proper image SKU was used", testAzureImageSkuUsed)
+	t.Run("test NIC has a public IP", testAzureNicHasPublicIp)
+	t.Run("test NSG rules are present", testAzureNsgRules)
+	t.Run("test resource group has infrastructure tag", testAzureInstanceInfraTagExists)
+}
+
+// testDestroyAzureWindowsInstance tests the deletion of a Windows VM and checks if the created
+// VM and NSG are deleted.
+func testDestroyAzureWindowsInstance(t *testing.T) {
+	t.Run("test VM is terminated", destroyingAzureWindowsInstance)
+	t.Run("test NSG is deleted", testAzureNsgIsDeleted)
+	t.Run("test installer json file is deleted", testAzureInstallerJsonFileIsDeleted)
+}
+
+// azureSetup creates the Azure cloud provider and uses it to spin up a Windows VM, updating the
+// test's global state for the rest of the suite to use.
+func azureSetup() error {
+	cloud, err := cloudprovider.CloudProviderFactory(kubeconfig, awscredentials, "default", artifactDir,
+		imageID, instanceType, sshKey, privateKeyPath, "Azure")
+	if err != nil {
+		return fmt.Errorf("error obtaining Azure interface object: %s", err)
+	}
+	provider, ok := cloud.(*azurecp.AzureProvider)
+	if !ok {
+		return fmt.Errorf("error asserting cloudprovider to azureProvider")
+	}
+	azureProvider = provider
+
+	if _, err = azureProvider.CreateWindowsVM(); err != nil {
+		return fmt.Errorf("error creating Windows VM: %s", err)
+	}
+
+	azureInfraID, err = azureProvider.GetInfraID()
+	if err != nil {
+		return fmt.Errorf("error getting infrastructure ID: %s", err)
+	}
+
+	info, err := resource.ReadInstallerInfo(artifactDir + "/" + "windows-node-installer.json")
+	if err != nil {
+		return fmt.Errorf("error reading from windows-node-installer.json file: %s", err)
+	}
+	if len(info.InstanceIDs) != 1 || info.InstanceIDs[0] == "" {
+		return fmt.Errorf("expected one Windows VM name, found %v", info.InstanceIDs)
+	}
+	if len(info.SecurityGroupIDs) != 1 || info.SecurityGroupIDs[0] == "" {
+		return fmt.Errorf("expected one NSG name, found %v", info.SecurityGroupIDs)
+	}
+	createdAzureInstanceName = info.InstanceIDs[0]
+	createdAzureNsgName = info.SecurityGroupIDs[0]
+	return nil
+}
+
+// tearDownAzureInstance removes the lingering VM and NSG when required steps of the test fail.
+func tearDownAzureInstance() error {
+	if createdAzureInstanceName != "" {
+		if err := azureProvider.TerminateInstance(createdAzureInstanceName); err != nil {
+			return fmt.Errorf("error terminating VM during teardown, %v", err)
+		}
+	}
+	createdAzureInstanceName = ""
+
+	if createdAzureNsgName != "" {
+		if err := azureProvider.DeleteSG(createdAzureNsgName); err != nil {
+			return fmt.Errorf("error deleting NSG during teardown, %v", err)
+		}
+	}
+	createdAzureNsgName = ""
+	return nil
+}
+
+// testAzureImageSkuUsed asserts that the created VM used the Windows Server 2019 with Containers
+// image SKU.
+func testAzureImageSkuUsed(t *testing.T) {
+	vm, err := azureProvider.VM.Get(context.TODO(), azureProvider.GetResourceGroupName(), createdAzureInstanceName, "")
+	require.NoError(t, err, "could not get VM %s", createdAzureInstanceName)
+	require.NotNil(t, vm.StorageProfile.ImageReference)
+	assert.Equal(t, "2019-Datacenter-with-Containers", *vm.StorageProfile.ImageReference.Sku)
+}
+
+// testAzureNicHasPublicIp asserts that the VM's NIC has a public IP address attached.
+func testAzureNicHasPublicIp(t *testing.T) {
+	nic, err := azureProvider.NIC.Get(context.TODO(), azureProvider.GetResourceGroupName(), azureInfraID+"-win-nic", "")
+	require.NoError(t, err, "could not get NIC for VM %s", createdAzureInstanceName)
+	require.NotNil(t, nic.IPConfigurations)
+	for _, ipConfig := range *nic.IPConfigurations {
+		if ipConfig.PublicIPAddress != nil {
+			return
+		}
+	}
+	assert.Fail(t, "NIC is not attached to a public IP address")
+}
+
+// testAzureNsgRules asserts that the Windows worker NSG allows RDP, SSH, and container-logs
+// traffic.
+func testAzureNsgRules(t *testing.T) {
+	nsg, err := azureProvider.NSG.Get(context.TODO(), azureProvider.GetResourceGroupName(), createdAzureNsgName, "")
+	require.NoError(t, err, "could not get NSG %s", createdAzureNsgName)
+	require.NotNil(t, nsg.SecurityRules)
+
+	ports := map[string]bool{"3389": false, "22": false, "10250": false}
+	for _, rule := range *nsg.SecurityRules {
+		if rule.DestinationPortRange != nil {
+			if _, ok := ports[*rule.DestinationPortRange]; ok {
+				ports[*rule.DestinationPortRange] = true
+			}
+		}
+	}
+	for port, found := range ports {
+		assert.Truef(t, found, "NSG is missing an ingress rule for port %s", port)
+	}
+}
+
+// testAzureInstanceInfraTagExists asserts that the cluster's infrastructure tag is present on
+// the created VM.
+func testAzureInstanceInfraTagExists(t *testing.T) {
+	vm, err := azureProvider.VM.Get(context.TODO(), azureProvider.GetResourceGroupName(), createdAzureInstanceName, "")
+	require.NoError(t, err, "could not get VM %s", createdAzureInstanceName)
+
+	key := "kubernetes.io_cluster_" + azureInfraID
+	value, ok := vm.Tags[key]
+	if !ok || value == nil || *value != "owned" {
+		assert.Fail(t, "infrastructure tag not found")
+	}
+}
+
+// destroyingAzureWindowsInstance destroys the Windows VM and asserts it no longer exists.
+func destroyingAzureWindowsInstance(t *testing.T) {
+	err := azureProvider.DestroyWindowsVMs()
+	require.NoError(t, err, "error destroying Windows VMs")
+
+	_, err = azureProvider.VM.Get(context.TODO(), azureProvider.GetResourceGroupName(), createdAzureInstanceName, "")
+	require.Error(t, err, "VM is not terminated")
+}
+
+// testAzureNsgIsDeleted asserts that the Windows worker NSG no longer exists.
+func testAzureNsgIsDeleted(t *testing.T) {
+	_, err := azureProvider.NSG.Get(context.TODO(), azureProvider.GetResourceGroupName(), createdAzureNsgName, "")
+	assert.Error(t, err, "NSG is not deleted")
+	createdAzureNsgName = ""
+}
+
+// testAzureInstallerJsonFileIsDeleted asserts that the windows-node-installer.json is deleted.
+func testAzureInstallerJsonFileIsDeleted(t *testing.T) {
+	_, err := resource.ReadInstallerInfo(artifactDir + "/" + "windows-node-installer.json")
+	assert.Error(t, err, "windows-node-installer.json file still exists")
+}